@@ -0,0 +1,204 @@
+package minions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Authenticator resolves the Principal behind a request. The returned status
+// code tells the caller how to proceed: http.StatusOK when the principal was
+// resolved, or a challenge status such as http.StatusUnauthorized otherwise.
+type Authenticator interface {
+	DoAuth(w http.ResponseWriter, r *http.Request) (Principal, int)
+}
+
+// LoginAuthenticator is implemented by authenticators that need to redirect
+// the visitor to a login page or an external provider, such as
+// OAuth2Authenticator, instead of authenticating the request directly.
+type LoginAuthenticator interface {
+	Login(w http.ResponseWriter, r *http.Request)
+}
+
+// CallbackAuthenticator is implemented by authenticators that resolve a
+// Principal from a provider redirect/callback, such as OAuth2Authenticator.
+type CallbackAuthenticator interface {
+	Callback(r *http.Request) (Principal, error)
+}
+
+// SessionStore persists the Principal resolved by an Authenticator between
+// requests, so the Authenticator does not have to run on every request.
+type SessionStore interface {
+	Load(r *http.Request) (Principal, error)
+	Save(w http.ResponseWriter, r *http.Request, principal Principal) error
+	Clear(w http.ResponseWriter, r *http.Request)
+}
+
+// BasicAuthenticator authenticates requests using HTTP Basic credentials.
+type BasicAuthenticator struct {
+	Realm  string
+	Verify func(user, pass string) (Principal, bool)
+}
+
+// DoAuth implements Authenticator.
+func (a BasicAuthenticator) DoAuth(w http.ResponseWriter, r *http.Request) (Principal, int) {
+	user, pass, ok := r.BasicAuth()
+	if ok {
+		if principal, ok := a.Verify(user, pass); ok {
+			return principal, http.StatusOK
+		}
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm="`+a.Realm+`"`)
+	return Anonymous{}, http.StatusUnauthorized
+}
+
+// CookieAuthenticator authenticates requests using a signed session cookie
+// and doubles as a SessionStore, since the cookie itself is the session.
+type CookieAuthenticator struct {
+	Name      string
+	Secret    []byte
+	MaxAge    time.Duration
+	Marshal   func(principal Principal) (string, error)
+	Unmarshal func(s string) (Principal, error)
+}
+
+// DoAuth implements Authenticator.
+func (a CookieAuthenticator) DoAuth(w http.ResponseWriter, r *http.Request) (Principal, int) {
+	principal, err := a.Load(r)
+	if err != nil {
+		return Anonymous{}, http.StatusUnauthorized
+	}
+	return principal, http.StatusOK
+}
+
+// Load implements SessionStore.
+func (a CookieAuthenticator) Load(r *http.Request) (Principal, error) {
+	c, err := r.Cookie(a.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(c.Value, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(a.sign(parts[0])), []byte(parts[1])) {
+		return nil, errors.New("minions: invalid session cookie")
+	}
+	value := parts[0]
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Unmarshal(string(raw))
+}
+
+// Save implements SessionStore.
+func (a CookieAuthenticator) Save(w http.ResponseWriter, r *http.Request, principal Principal) error {
+	s, err := a.Marshal(principal)
+	if err != nil {
+		return err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString([]byte(s))
+	http.SetCookie(w, &http.Cookie{
+		Name:     a.Name,
+		Value:    value + "." + a.sign(value),
+		Path:     "/",
+		MaxAge:   int(a.MaxAge.Seconds()),
+		HttpOnly: true,
+	})
+	return nil
+}
+
+// Clear implements SessionStore.
+func (a CookieAuthenticator) Clear(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   a.Name,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+func (a CookieAuthenticator) sign(value string) string {
+	mac := hmac.New(sha256.New, a.Secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// OAuth2Authenticator authenticates requests via an OAuth2/OIDC
+// authorization code flow. Login redirects the visitor to the provider's
+// consent page, Callback exchanges the returned code for a token and
+// resolves the principal behind it.
+type OAuth2Authenticator struct {
+	Config *oauth2.Config
+	State  func(r *http.Request) string
+	Fetch  func(ctx context.Context, token *oauth2.Token) (Principal, error)
+}
+
+// DoAuth implements Authenticator. An OAuth2 login always requires the
+// redirect/callback dance started by Login, so DoAuth never authenticates a
+// request on its own.
+func (a OAuth2Authenticator) DoAuth(w http.ResponseWriter, r *http.Request) (Principal, int) {
+	return Anonymous{}, http.StatusUnauthorized
+}
+
+// oauth2StateCookie carries the state generated by Login until Callback can
+// verify it against the provider's redirect, so a forged callback can't log
+// a visitor into an attacker's account (CSRF on the authorization code).
+const oauth2StateCookie = "_oauth2_state"
+
+// Login implements LoginAuthenticator.
+func (a OAuth2Authenticator) Login(w http.ResponseWriter, r *http.Request) {
+	state := a.State(r)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauth2StateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, a.Config.AuthCodeURL(state), http.StatusFound)
+}
+
+// Callback implements CallbackAuthenticator. It rejects the callback unless
+// the state returned by the provider matches the one Login persisted,
+// preventing an attacker from tricking a visitor into completing the
+// attacker's own OAuth2 login.
+func (a OAuth2Authenticator) Callback(r *http.Request) (Principal, error) {
+	c, err := r.Cookie(oauth2StateCookie)
+	if err != nil || !hmac.Equal([]byte(c.Value), []byte(r.URL.Query().Get("state"))) {
+		return nil, errors.New("minions: oauth2 state mismatch")
+	}
+
+	token, err := a.Config.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		return nil, err
+	}
+	return a.Fetch(r.Context(), token)
+}
+
+// MockAuthenticator always resolves the same fixed Principal, useful for
+// tests and local development.
+type MockAuthenticator struct {
+	Principal Principal
+}
+
+// DoAuth implements Authenticator.
+func (a MockAuthenticator) DoAuth(w http.ResponseWriter, r *http.Request) (Principal, int) {
+	if a.Principal == nil {
+		return Anonymous{}, http.StatusUnauthorized
+	}
+	return a.Principal, http.StatusOK
+}
@@ -0,0 +1,157 @@
+package minions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestCookieAuthenticatorRoundTrip(t *testing.T) {
+	auth := CookieAuthenticator{
+		Name:   "session",
+		Secret: []byte("secret"),
+		MaxAge: time.Hour,
+		Marshal: func(p Principal) (string, error) {
+			return p.ID(), nil
+		},
+		Unmarshal: func(s string) (Principal, error) {
+			return TestUser{
+				FnID:            func() string { return s },
+				FnAuthenticated: func() bool { return true },
+				FnHasAnyRole:    func(roles ...string) bool { return false },
+			}, nil
+		},
+	}
+
+	principal := TestUser{FnID: func() string { return "42" }}
+
+	rec := httptest.NewRecorder()
+	if err := auth.Save(rec, httptest.NewRequest(http.MethodGet, "/", nil), principal); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %d", len(cookies))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+
+	loaded, err := auth.Load(req)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.ID() != "42" {
+		t.Errorf("expected ID %q, got %q", "42", loaded.ID())
+	}
+}
+
+func TestCookieAuthenticatorDetectsTampering(t *testing.T) {
+	auth := CookieAuthenticator{
+		Name:   "session",
+		Secret: []byte("secret"),
+		Marshal: func(p Principal) (string, error) {
+			return p.ID(), nil
+		},
+		Unmarshal: func(s string) (Principal, error) {
+			return TestUser{FnID: func() string { return s }}, nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := auth.Save(rec, httptest.NewRequest(http.MethodGet, "/", nil), TestUser{FnID: func() string { return "42" }}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cookie := rec.Result().Cookies()[0]
+	cookie.Value = cookie.Value + "tampered"
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+
+	if _, err := auth.Load(req); err == nil {
+		t.Error("expected a tampered cookie to be rejected")
+	}
+}
+
+func TestCookieAuthenticatorClear(t *testing.T) {
+	auth := CookieAuthenticator{Name: "session", Secret: []byte("secret")}
+
+	rec := httptest.NewRecorder()
+	auth.Clear(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("expected Clear to expire the session cookie, got %v", cookies)
+	}
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	auth := BasicAuthenticator{
+		Realm: "test",
+		Verify: func(user, pass string) (Principal, bool) {
+			if user == "alice" && pass == "secret" {
+				return TestUser{FnID: func() string { return "alice" }}, true
+			}
+			return nil, false
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("alice", "secret")
+
+	principal, status := auth.DoAuth(httptest.NewRecorder(), req)
+	if status != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, status)
+	}
+	if principal.ID() != "alice" {
+		t.Errorf("expected ID %q, got %q", "alice", principal.ID())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("alice", "wrong")
+
+	if _, status := auth.DoAuth(httptest.NewRecorder(), req); status != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, status)
+	}
+}
+
+func TestOAuth2AuthenticatorCallbackRejectsStateMismatch(t *testing.T) {
+	auth := OAuth2Authenticator{
+		Config: &oauth2.Config{},
+		State:  func(r *http.Request) string { return "expected" },
+		Fetch: func(ctx context.Context, token *oauth2.Token) (Principal, error) {
+			t.Fatal("Fetch should not be called when the state does not match")
+			return nil, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?state=attacker-controlled&code=stolen-code", nil)
+	req.AddCookie(&http.Cookie{Name: oauth2StateCookie, Value: "expected"})
+
+	if _, err := auth.Callback(req); err == nil {
+		t.Error("expected a state mismatch to be rejected")
+	}
+}
+
+func TestOAuth2AuthenticatorCallbackRejectsMissingStateCookie(t *testing.T) {
+	auth := OAuth2Authenticator{
+		Config: &oauth2.Config{},
+		State:  func(r *http.Request) string { return "expected" },
+		Fetch: func(ctx context.Context, token *oauth2.Token) (Principal, error) {
+			t.Fatal("Fetch should not be called without a state cookie")
+			return nil, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?state=expected&code=abc", nil)
+
+	if _, err := auth.Callback(req); err == nil {
+		t.Error("expected a missing state cookie to be rejected")
+	}
+}
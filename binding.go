@@ -0,0 +1,317 @@
+package minions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Binder is implemented by types that want full control over how they are
+// populated from request values, bypassing the struct tag reflection done
+// by Bind entirely.
+type Binder interface {
+	Bind(values url.Values) error
+}
+
+// PathParamFn extracts path/route parameters for a request, so Bind can
+// populate fields tagged `path:"..."`. The default returns no parameters;
+// overwrite it to plug in your router, e.g. chi.URLParam or mux.Vars.
+var PathParamFn = func(r *http.Request) map[string]string {
+	return nil
+}
+
+// TimeLayout is the default layout used to parse a time.Time field that
+// doesn't specify its own `layout:"..."` tag.
+var TimeLayout = time.RFC3339
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// Bind populates dst from r using `form`, `json`, `query` and `path` struct
+// tags, converts the matched values to the destination field's kind and
+// validates the result using `validate` tags. dst must be a pointer to a
+// struct. Nested structs are bound recursively and fields implementing
+// Binder are delegated to entirely.
+func Bind(r *http.Request, dst interface{}) BindingResult {
+	result := BindingResult{}
+
+	if err := r.ParseForm(); err != nil {
+		result.Fail("_", err.Error())
+		return result
+	}
+
+	var body map[string]interface{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") && r.Body != nil {
+		body = map[string]interface{}{}
+		defer r.Body.Close()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		result.Fail("_", "minions: Bind requires a pointer to a struct")
+		return result
+	}
+
+	bind(v.Elem(), r.Form, r.PostForm, r.URL.Query(), body, PathParamFn(r), result, "")
+	return result
+}
+
+func bind(v reflect.Value, merged, form, query url.Values, body map[string]interface{}, path map[string]string, result BindingResult, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.PkgPath != "" || !fv.CanSet() {
+			continue
+		}
+
+		label := fieldLabel(field, prefix)
+
+		if fv.CanAddr() {
+			if binder, ok := fv.Addr().Interface().(Binder); ok {
+				if err := binder.Bind(merged); err != nil {
+					result.Fail(label, err.Error())
+				}
+				continue
+			}
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+			bind(fv, merged, form, query, body, path, result, label)
+			continue
+		}
+
+		raw, found := resolve(field, form, query, body, path)
+		if !found {
+			validateField(field, label, "", result)
+			continue
+		}
+
+		if err := assign(fv, field, raw); err != nil {
+			result.Fail(label, err.Error())
+			continue
+		}
+
+		first := ""
+		if len(raw) > 0 {
+			first = raw[0]
+		}
+		validateField(field, label, first, result)
+	}
+}
+
+// resolve looks up the values for field, trying the form, json, query and
+// path tags in that order. Only the first tag present on the field is used.
+// form is read from r.PostForm, so it only ever sees body values, and query
+// is read from r.URL.Query(), so it only ever sees URL values — the two tags
+// never leak into each other the way r.Form (which merges both) would.
+func resolve(field reflect.StructField, form, query url.Values, body map[string]interface{}, path map[string]string) ([]string, bool) {
+	if name, ok := field.Tag.Lookup("form"); ok {
+		values, found := form[name]
+		return values, found
+	}
+
+	if name, ok := field.Tag.Lookup("json"); ok {
+		if body == nil {
+			return nil, false
+		}
+		value, found := body[name]
+		if !found {
+			return nil, false
+		}
+		return []string{fmt.Sprintf("%v", value)}, true
+	}
+
+	if name, ok := field.Tag.Lookup("query"); ok {
+		values, found := query[name]
+		return values, found
+	}
+
+	if name, ok := field.Tag.Lookup("path"); ok {
+		value, found := path[name]
+		if !found {
+			return nil, false
+		}
+		return []string{value}, true
+	}
+
+	return nil, false
+}
+
+// assign converts raw into fv's Go type, handling time.Time, slices of
+// repeated form values, and plain scalars.
+func assign(fv reflect.Value, field reflect.StructField, raw []string) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if fv.Type() == timeType {
+		layout := field.Tag.Get("layout")
+		if layout == "" {
+			layout = TimeLayout
+		}
+
+		parsed, err := time.Parse(layout, raw[0])
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(raw), len(raw))
+		for i, s := range raw {
+			if err := assignScalar(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return assignScalar(fv, raw[0])
+}
+
+func assignScalar(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("minions: unsupported field kind %s", fv.Kind())
+	}
+	return nil
+}
+
+// validateRule is a single parsed piece of a `validate` tag, e.g.
+// {name: "min", arg: "3"} for "min=3".
+type validateRule struct {
+	name string
+	arg  string
+}
+
+// splitValidateRules splits a validate tag into its rules. Unlike a plain
+// strings.Split(tag, ","), it does not split inside a regex= argument: a
+// regular expression may itself contain commas or brace quantifiers such as
+// {2,4} that must survive intact, so everything from "regex=" to the end of
+// the tag is kept as a single rule. This means a regex rule must be the
+// last one in the tag, matching the order used throughout this package.
+func splitValidateRules(tag string) []validateRule {
+	head := tag
+	hasRegex := false
+	regexArg := ""
+
+	if i := strings.Index(tag, "regex="); i >= 0 {
+		head = strings.TrimSuffix(tag[:i], ",")
+		hasRegex = true
+		regexArg = tag[i+len("regex="):]
+	}
+
+	var rules []validateRule
+	if head != "" {
+		for _, part := range strings.Split(head, ",") {
+			name, arg := part, ""
+			if i := strings.Index(part, "="); i >= 0 {
+				name, arg = part[:i], part[i+1:]
+			}
+			rules = append(rules, validateRule{name: name, arg: arg})
+		}
+	}
+
+	if hasRegex {
+		rules = append(rules, validateRule{name: "regex", arg: regexArg})
+	}
+
+	return rules
+}
+
+// validateField applies the `validate` rules of field to value, stopping at
+// the first rule that fails.
+func validateField(field reflect.StructField, label, value string, result BindingResult) {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return
+	}
+
+	for _, rule := range splitValidateRules(tag) {
+		name, arg := rule.name, rule.arg
+
+		switch name {
+		case "required":
+			if value == "" {
+				result.Fail(label, label+" is required")
+				return
+			}
+		case "email":
+			if value != "" && !emailPattern.MatchString(value) {
+				result.Fail(label, label+" is not a valid email address")
+				return
+			}
+		case "min":
+			if n, err := strconv.Atoi(arg); err == nil && len(value) < n {
+				result.Fail(label, fmt.Sprintf("%s must be at least %d characters", label, n))
+				return
+			}
+		case "max":
+			if n, err := strconv.Atoi(arg); err == nil && len(value) > n {
+				result.Fail(label, fmt.Sprintf("%s must be at most %d characters", label, n))
+				return
+			}
+		case "regex":
+			if re, err := regexp.Compile(arg); err == nil && value != "" && !re.MatchString(value) {
+				result.Fail(label, label+" has an invalid format")
+				return
+			}
+		}
+	}
+}
+
+// fieldLabel returns the name used to report binding/validation errors for
+// field: the tag value it was bound from if any, otherwise the field name,
+// optionally namespaced by prefix for nested structs.
+func fieldLabel(field reflect.StructField, prefix string) string {
+	name := field.Name
+	for _, tag := range []string{"form", "json", "query", "path"} {
+		if v, ok := field.Tag.Lookup(tag); ok && v != "" {
+			name = v
+			break
+		}
+	}
+
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,128 @@
+package minions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBindForm(t *testing.T) {
+	type SignupForm struct {
+		Name  string   `form:"name" validate:"required,min=3"`
+		Email string   `form:"email" validate:"required,email"`
+		Tags  []string `form:"tags"`
+	}
+
+	body := strings.NewReader(url.Values{
+		"name":  {"jo"},
+		"email": {"not-an-email"},
+		"tags":  {"a", "b"},
+	}.Encode())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst SignupForm
+	result := Bind(req, &dst)
+
+	if result.Valid() {
+		t.Fatal("expected binding to fail validation")
+	}
+	if _, ok := result["name"]; !ok {
+		t.Error("expected a validation error for name")
+	}
+	if _, ok := result["email"]; !ok {
+		t.Error("expected a validation error for email")
+	}
+	if len(dst.Tags) != 2 || dst.Tags[0] != "a" || dst.Tags[1] != "b" {
+		t.Errorf("expected Tags to be [a b], got %v", dst.Tags)
+	}
+}
+
+func TestBindFormAndQueryDoNotLeakIntoEachOther(t *testing.T) {
+	type Search struct {
+		Q      string `query:"q"`
+		Ignore string `form:"q"`
+	}
+
+	body := strings.NewReader(url.Values{"q": {"from-body"}}.Encode())
+	req := httptest.NewRequest(http.MethodPost, "/?q=from-query", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst Search
+	result := Bind(req, &dst)
+
+	if !result.Valid() {
+		t.Fatalf("expected binding to succeed, got %v", result)
+	}
+	if dst.Q != "from-query" {
+		t.Errorf("expected query tag to read %q, got %q", "from-query", dst.Q)
+	}
+	if dst.Ignore != "from-body" {
+		t.Errorf("expected form tag to read %q, got %q", "from-body", dst.Ignore)
+	}
+}
+
+func TestBindValidateRegexWithBraceQuantifierAndComma(t *testing.T) {
+	type Coupon struct {
+		// {2,4} exercises a comma inside the regex itself, which a naive
+		// strings.Split(tag, ",") would chop into bogus extra rules.
+		Code string `form:"code" validate:"required,regex=^[A-Z]{2,4}$"`
+	}
+
+	body := strings.NewReader(url.Values{"code": {"AB"}}.Encode())
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst Coupon
+	result := Bind(req, &dst)
+	if !result.Valid() {
+		t.Fatalf("expected a 2-4 letter code to pass validation, got %v", result)
+	}
+
+	body = strings.NewReader(url.Values{"code": {"TOOLONG"}}.Encode())
+	req = httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	dst = Coupon{}
+	result = Bind(req, &dst)
+	if result.Valid() {
+		t.Fatal("expected a 7 letter code to fail the {2,4} quantifier")
+	}
+}
+
+func TestBindRequiresPointerToStruct(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	var dst string
+	result := Bind(req, &dst)
+	if result.Valid() {
+		t.Fatal("expected binding to fail for a non-struct destination")
+	}
+}
+
+func TestBindNestedStruct(t *testing.T) {
+	type Address struct {
+		City string `form:"city" validate:"required"`
+	}
+	type Profile struct {
+		Name    string `form:"name"`
+		Address Address
+	}
+
+	body := strings.NewReader(url.Values{"name": {"Ann"}, "city": {"Vienna"}}.Encode())
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst Profile
+	result := Bind(req, &dst)
+
+	if !result.Valid() {
+		t.Fatalf("expected binding to succeed, got %v", result)
+	}
+	if dst.Address.City != "Vienna" {
+		t.Errorf("expected City to be Vienna, got %q", dst.Address.City)
+	}
+}
@@ -0,0 +1,130 @@
+package minions
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var errForNoSession = errors.New("minions: no session")
+
+func TestCSRFRejectsUnsafeRequestWithoutToken(t *testing.T) {
+	guard := NewGuard().CSRFSecret([]byte("secret"))
+
+	handler := guard.CSRF()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("protected handler should not be called")
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestCSRFAllowsUnsafeRequestWithMatchingToken(t *testing.T) {
+	guard := NewGuard().CSRFSecret([]byte("secret"))
+
+	var called bool
+	handler := guard.CSRF()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	// fetch a token via a safe request first, as the browser would
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a csrf cookie to be set, got %d cookies", len(cookies))
+	}
+
+	tokenReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	tokenReq.AddCookie(cookies[0])
+	token := guard.Token(httptest.NewRecorder(), tokenReq)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(cookies[0])
+	handler.ServeHTTP(rec, req)
+
+	if !called {
+		t.Error("protected handler was not called")
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+type stubSessionStore struct {
+	principal Principal
+}
+
+func (s stubSessionStore) Load(r *http.Request) (Principal, error) {
+	if s.principal == nil {
+		return nil, errForNoSession
+	}
+	return s.principal, nil
+}
+
+func (s stubSessionStore) Save(w http.ResponseWriter, r *http.Request, principal Principal) error {
+	return nil
+}
+
+func (s stubSessionStore) Clear(w http.ResponseWriter, r *http.Request) {}
+
+func TestTokenIsBoundToSessionStore(t *testing.T) {
+	alice := TestUser{FnID: func() string { return "alice" }}
+	bob := TestUser{FnID: func() string { return "bob" }}
+
+	guard := NewGuard().CSRFSecret([]byte("secret")).SessionStore(stubSessionStore{principal: alice})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	rec := httptest.NewRecorder()
+	token := guard.Token(rec, req)
+	if len(rec.Result().Cookies()) != 0 {
+		t.Error("expected no csrf cookie to be set when a SessionStore is configured")
+	}
+
+	sameToken := guard.Token(httptest.NewRecorder(), req)
+	if token != sameToken {
+		t.Error("expected the token for the same principal to be stable")
+	}
+
+	guard2 := NewGuard().CSRFSecret([]byte("secret")).SessionStore(stubSessionStore{principal: bob})
+	if bobToken := guard2.Token(httptest.NewRecorder(), req); bobToken == token {
+		t.Error("expected different principals to get different tokens")
+	}
+}
+
+func TestSecureHeadersDefaults(t *testing.T) {
+	handler := SecureHeaders(SecureHeadersOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("expected X-Frame-Options DENY, got %q", rec.Header().Get("X-Frame-Options"))
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("expected X-Content-Type-Options nosniff, got %q", rec.Header().Get("X-Content-Type-Options"))
+	}
+}
+
+func TestSecureHeadersOmitted(t *testing.T) {
+	handler := SecureHeaders(SecureHeadersOptions{HSTS: "-"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(rec, req)
+
+	if v := rec.Header().Get("Strict-Transport-Security"); v != "" {
+		t.Errorf("expected Strict-Transport-Security to be omitted, got %q", v)
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 // JSON outputs the data encoded as JSON.
@@ -45,6 +48,15 @@ type Templates struct {
 	templates *template.Template
 	funcmap   template.FuncMap
 	reload    bool
+
+	watched bool
+	cache   *atomic.Value
+	watcher *fsnotify.Watcher
+
+	// onReloadError is itself boxed in an atomic.Value, since the watch()
+	// goroutine is started before callers have a chance to attach a
+	// handler via OnReloadError, and Templates is copied by value.
+	onReloadError *atomic.Value
 }
 
 // NewTemplates creates a new template collection. The templates are loaded from dir
@@ -126,17 +138,24 @@ func (tpl Templates) HTML(w http.ResponseWriter, r *http.Request, code int, name
 // Execute outputs a rendered template to the Writer. If you want to stream
 // HTML to an ResponseWriter, use HTML(..) as it sets some required headers.
 func (tpl Templates) Execute(w io.Writer, name string, data interface{}) error {
-	// reload templates in debug mode
-	if tpl.reload {
+	parsed := tpl.templates
+
+	if tpl.watched {
+		// the cache is updated by watch() as templates change on disk, so
+		// reading it here never takes a lock on the hot path
+		parsed = tpl.cache.Load().(*template.Template)
+	} else if tpl.reload {
+		// reload templates in debug mode
 		var err error
 		tpl, err = tpl.Load()
 		if err != nil {
 			return err
 		}
+		parsed = tpl.templates
 	}
 
 	// clone underlying templates, so we can safely update the functions
-	templates, err := tpl.templates.Clone()
+	templates, err := parsed.Clone()
 	if err != nil {
 		return err
 	}
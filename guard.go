@@ -4,12 +4,16 @@ import "net/http"
 
 // Guard enforces a role based security model on protected resources. Before
 // a visitor can access a procted resource, he must be authenticated and have
-// the required roles to access. Authentication is outside the scope of the
-// guard, the principal is fetched using a provided PrincipalFn.
+// the required roles to access. Authentication is handled by a PrincipalFn
+// or, for the built-in login flow, by a configured Authenticator and
+// SessionStore.
 type Guard struct {
-	unauthorized http.HandlerFunc
-	forbidden    http.HandlerFunc
-	principal    func(r *http.Request) Principal
+	unauthorized  http.HandlerFunc
+	forbidden     http.HandlerFunc
+	principal     func(r *http.Request) Principal
+	authenticator Authenticator
+	sessions      SessionStore
+	csrfSecret    []byte
 }
 
 // NewGuard creates a new guard. You wan't to overwrite at lest the PrincipalFn
@@ -51,11 +55,27 @@ func (g *Guard) ForbiddenFn(fn http.HandlerFunc) *Guard {
 	return g
 }
 
+// Authenticator overwrites the authenticator used to resolve a principal for
+// requests that carry no valid session yet. The return value is the guard,
+// so calls can be chained.
+func (g *Guard) Authenticator(a Authenticator) *Guard {
+	g.authenticator = a
+	return g
+}
+
+// SessionStore overwrites the store used to persist the principal resolved
+// by the Authenticator between requests. The return value is the guard, so
+// calls can be chained.
+func (g *Guard) SessionStore(s SessionStore) *Guard {
+	g.sessions = s
+	return g
+}
+
 // Protect requires that the principal has at least one of the provided roles before
 // the request is forwarded to the protected handler.
 func (g *Guard) Protect(fn http.HandlerFunc, roles ...string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		principal := g.principal(r)
+		principal := g.authenticate(w, r)
 		if !principal.Authenticated() {
 			g.unauthorized(w, r)
 			return
@@ -70,6 +90,114 @@ func (g *Guard) Protect(fn http.HandlerFunc, roles ...string) http.HandlerFunc {
 	}
 }
 
+// ProtectFunc requires that decide allows the request before it is forwarded
+// to the protected handler. Unlike Protect, it is not limited to "has any of
+// these roles" checks; compose decide from All, Any, HasRole, HasPermission
+// and Owns to express richer authorization rules.
+func (g *Guard) ProtectFunc(fn http.HandlerFunc, decide DecisionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := g.authenticate(w, r)
+
+		switch decide(principal, r) {
+		case Allow:
+			fn(w, r)
+		case Challenge:
+			g.unauthorized(w, r)
+		default:
+			g.forbidden(w, r)
+		}
+	}
+}
+
+// authenticate resolves the principal for r. It prefers a principal already
+// persisted in the SessionStore and falls back to running the configured
+// Authenticator; if that fails, the result is Anonymous. Only when no
+// Authenticator is configured is the legacy PrincipalFn used, so guards
+// that never adopt the new authentication subsystem keep working unchanged.
+func (g *Guard) authenticate(w http.ResponseWriter, r *http.Request) Principal {
+	if g.sessions != nil {
+		if principal, err := g.sessions.Load(r); err == nil {
+			return principal
+		}
+	}
+
+	if g.authenticator != nil {
+		principal, status := g.authenticator.DoAuth(w, r)
+		if status != http.StatusOK {
+			return Anonymous{}
+		}
+
+		if g.sessions != nil {
+			g.sessions.Save(w, r, principal)
+		}
+		return principal
+	}
+
+	return g.principal(r)
+}
+
+// LoginHandler starts the login flow of the configured Authenticator. For a
+// LoginAuthenticator, such as OAuth2Authenticator, this redirects the
+// visitor to the provider; for any other Authenticator it runs DoAuth
+// directly, as is appropriate for e.g. BasicAuthenticator, and persists the
+// resulting principal in the SessionStore.
+func (g *Guard) LoginHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.authenticator == nil {
+			g.unauthorized(w, r)
+			return
+		}
+
+		if login, ok := g.authenticator.(LoginAuthenticator); ok {
+			login.Login(w, r)
+			return
+		}
+
+		principal, status := g.authenticator.DoAuth(w, r)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		if g.sessions != nil {
+			g.sessions.Save(w, r, principal)
+		}
+	}
+}
+
+// CallbackHandler completes a redirect based login flow, such as an OAuth2
+// authorization code exchange, and persists the resolved principal in the
+// SessionStore. The configured Authenticator must implement
+// CallbackAuthenticator.
+func (g *Guard) CallbackHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		callback, ok := g.authenticator.(CallbackAuthenticator)
+		if !ok {
+			g.unauthorized(w, r)
+			return
+		}
+
+		principal, err := callback.Callback(r)
+		if err != nil {
+			g.unauthorized(w, r)
+			return
+		}
+
+		if g.sessions != nil {
+			g.sessions.Save(w, r, principal)
+		}
+	}
+}
+
+// LogoutHandler clears the principal persisted by the SessionStore.
+func (g *Guard) LogoutHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.sessions != nil {
+			g.sessions.Clear(w, r)
+		}
+	}
+}
+
 // Principal is an entity that can be authenticated and verified.
 type Principal interface {
 	ID() string
@@ -0,0 +1,102 @@
+package minions
+
+import "net/http"
+
+// Decision is the outcome of an authorization check performed by a
+// DecisionFunc passed to Guard.ProtectFunc.
+type Decision int
+
+const (
+	// Deny rejects the request; Guard.forbidden is invoked.
+	Deny Decision = iota
+	// Allow lets the request reach the protected handler.
+	Allow
+	// Challenge rejects the request as if the principal was never
+	// authenticated; Guard.unauthorized is invoked.
+	Challenge
+)
+
+// DecisionFunc decides whether principal may access the resource addressed
+// by r.
+type DecisionFunc func(principal Principal, r *http.Request) Decision
+
+// PermissionedPrincipal is implemented by principals that carry fine grained
+// permissions in addition to roles. HasPermission checks for this
+// interface via a type assertion, so existing Principal implementations
+// keep working unchanged.
+type PermissionedPrincipal interface {
+	Principal
+	Permissions() []string
+}
+
+// All combines decisions so that access is only allowed when every one of
+// them allows it. The first non-Allow decision wins.
+func All(decisions ...DecisionFunc) DecisionFunc {
+	return func(principal Principal, r *http.Request) Decision {
+		for _, decide := range decisions {
+			if decision := decide(principal, r); decision != Allow {
+				return decision
+			}
+		}
+		return Allow
+	}
+}
+
+// Any combines decisions so that access is allowed as soon as one of them
+// allows it. If none allow it, the last decision is returned.
+func Any(decisions ...DecisionFunc) DecisionFunc {
+	return func(principal Principal, r *http.Request) Decision {
+		decision := Deny
+		for _, decide := range decisions {
+			decision = decide(principal, r)
+			if decision == Allow {
+				return Allow
+			}
+		}
+		return decision
+	}
+}
+
+// HasRole allows the request when the principal has at least one of roles.
+func HasRole(roles ...string) DecisionFunc {
+	return func(principal Principal, r *http.Request) Decision {
+		if principal.HasAnyRole(roles...) {
+			return Allow
+		}
+		return Deny
+	}
+}
+
+// HasPermission allows the request when the principal carries at least one
+// of permissions. Principals that don't implement PermissionedPrincipal
+// never have any permissions and are always denied.
+func HasPermission(permissions ...string) DecisionFunc {
+	return func(principal Principal, r *http.Request) Decision {
+		granted, ok := principal.(PermissionedPrincipal)
+		if !ok {
+			return Deny
+		}
+
+		for _, have := range granted.Permissions() {
+			for _, want := range permissions {
+				if have == want {
+					return Allow
+				}
+			}
+		}
+		return Deny
+	}
+}
+
+// Owns allows the request when id(r) equals the principal's ID. Use it to
+// express that a visitor may only access resources they own, e.g. "editor
+// role AND owns the resource in URL param id" via
+// All(HasRole("editor"), Owns(func(r) string { ... })).
+func Owns(id func(r *http.Request) string) DecisionFunc {
+	return func(principal Principal, r *http.Request) Decision {
+		if principal.Authenticated() && principal.ID() == id(r) {
+			return Allow
+		}
+		return Deny
+	}
+}
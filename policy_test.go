@@ -0,0 +1,107 @@
+package minions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type TestPermissionedUser struct {
+	TestUser
+	FnPermissions func() []string
+}
+
+func (u TestPermissionedUser) Permissions() []string { return u.FnPermissions() }
+
+func TestHasRole(t *testing.T) {
+	principal := TestUser{FnHasAnyRole: func(roles ...string) bool { return roles[0] == "editor" }}
+
+	if decision := HasRole("editor")(principal, nil); decision != Allow {
+		t.Errorf("expected Allow, got %v", decision)
+	}
+	if decision := HasRole("admin")(principal, nil); decision != Deny {
+		t.Errorf("expected Deny, got %v", decision)
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	principal := TestPermissionedUser{FnPermissions: func() []string { return []string{"doc:read"} }}
+
+	if decision := HasPermission("doc:read")(principal, nil); decision != Allow {
+		t.Errorf("expected Allow, got %v", decision)
+	}
+	if decision := HasPermission("doc:write")(principal, nil); decision != Deny {
+		t.Errorf("expected Deny, got %v", decision)
+	}
+
+	plain := TestUser{}
+	if decision := HasPermission("doc:read")(plain, nil); decision != Deny {
+		t.Errorf("expected Deny for a principal without permissions, got %v", decision)
+	}
+}
+
+func TestOwns(t *testing.T) {
+	principal := TestUser{
+		FnID:            func() string { return "42" },
+		FnAuthenticated: func() bool { return true },
+	}
+	id := func(r *http.Request) string { return r.URL.Query().Get("id") }
+
+	req := httptest.NewRequest(http.MethodGet, "/?id=42", nil)
+	if decision := Owns(id)(principal, req); decision != Allow {
+		t.Errorf("expected Allow, got %v", decision)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+	if decision := Owns(id)(principal, req); decision != Deny {
+		t.Errorf("expected Deny, got %v", decision)
+	}
+}
+
+func TestAllRequiresEveryDecision(t *testing.T) {
+	principal := TestUser{
+		FnID:            func() string { return "42" },
+		FnAuthenticated: func() bool { return true },
+		FnHasAnyRole:    func(roles ...string) bool { return true },
+	}
+	id := func(r *http.Request) string { return "1" }
+
+	decide := All(HasRole("editor"), Owns(id))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if decision := decide(principal, req); decision != Deny {
+		t.Errorf("expected Deny, got %v", decision)
+	}
+}
+
+func TestAnyAllowsOnFirstMatch(t *testing.T) {
+	principal := TestUser{FnHasAnyRole: func(roles ...string) bool { return roles[0] == "admin" }}
+
+	decide := Any(HasRole("editor"), HasRole("admin"))
+	if decision := decide(principal, nil); decision != Allow {
+		t.Errorf("expected Allow, got %v", decision)
+	}
+}
+
+func TestProtectFunc(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	var called200, called401, called403 bool
+	fn200 := func(w http.ResponseWriter, r *http.Request) { called200 = true }
+	fn401 := func(w http.ResponseWriter, r *http.Request) { called401 = true }
+	fn403 := func(w http.ResponseWriter, r *http.Request) { called403 = true }
+
+	NewGuard().
+		UnauthorizedFn(fn401).
+		ForbiddenFn(fn403).
+		ProtectFunc(fn200, func(principal Principal, r *http.Request) Decision {
+			return Allow
+		})(rec, req)
+
+	if !called200 {
+		t.Error("200 handler _NOT_ called")
+	}
+	if called401 || called403 {
+		t.Error("unexpected handler called")
+	}
+}
@@ -0,0 +1,187 @@
+package minions
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Encoder writes data to the response in its own wire format. Its signature
+// matches JSON and XML, so either can be registered on a Renderer directly.
+// Templates.HTML also takes a template name, so NewRenderer registers it
+// through a wrapper that reads the name from the data.
+type Encoder func(w http.ResponseWriter, r *http.Request, code int, data interface{}) error
+
+// Renderable is implemented by values that know how to render themselves,
+// bypassing content negotiation and the registered Encoder entirely.
+type Renderable interface {
+	Render(w http.ResponseWriter, r *http.Request, code int) error
+}
+
+// Renderer consolidates JSON, XML and Templates.HTML behind a single Render
+// call that picks the right Encoder based on the Accept header, the
+// ?format= query parameter and the request's file extension.
+type Renderer struct {
+	Templates   Templates
+	encoders    map[string]Encoder
+	defaultMIME string
+}
+
+// formatAliases maps the short names accepted by ?format= and file
+// extensions to the mime type of their Encoder.
+var formatAliases = map[string]string{
+	"json": "application/json",
+	"xml":  "application/xml",
+	"html": "text/html",
+	"yaml": "application/yaml",
+	"yml":  "application/yaml",
+	"text": "text/plain",
+	"txt":  "text/plain",
+}
+
+// NewRenderer creates a Renderer backed by tpl for the "html" format, with
+// JSON, XML, YAML and plain text encoders registered out of the box.
+func NewRenderer(tpl Templates) *Renderer {
+	rr := &Renderer{
+		Templates:   tpl,
+		encoders:    map[string]Encoder{},
+		defaultMIME: "application/json",
+	}
+
+	rr.RegisterEncoder("application/json", JSON)
+	rr.RegisterEncoder("application/xml", XML)
+	rr.RegisterEncoder("text/html", rr.encodeHTML)
+	rr.RegisterEncoder("application/yaml", encodeYAML)
+	rr.RegisterEncoder("text/plain", encodeText)
+	return rr
+}
+
+// RegisterEncoder adds or overwrites the Encoder used for mime. Use it to
+// plug in MsgPack, Protobuf, CBOR or any other wire format.
+func (rr *Renderer) RegisterEncoder(mime string, enc Encoder) {
+	rr.encoders[mime] = enc
+}
+
+// DefaultMIME overwrites the mime type used when the request has no ?format=
+// parameter and neither its file extension nor its Accept header selects a
+// registered encoder. An unknown ?format= value does not fall back to it.
+func (rr *Renderer) DefaultMIME(mime string) {
+	rr.defaultMIME = mime
+}
+
+// Render picks an Encoder for r and writes data with it, responding with
+// 406 Not Acceptable if no encoder is registered for the picked mime type,
+// i.e. for an unknown ?format= value or an unregistered DefaultMIME. An
+// Accept header that names no registered encoder falls back to DefaultMIME.
+func (rr *Renderer) Render(w http.ResponseWriter, r *http.Request, code int, data interface{}) error {
+	if renderable, ok := data.(Renderable); ok {
+		return renderable.Render(w, r, code)
+	}
+
+	mime := rr.negotiate(r)
+	enc, ok := rr.encoders[mime]
+	if !ok {
+		http.Error(w, "Not Acceptable", http.StatusNotAcceptable)
+		return fmt.Errorf("minions: no encoder registered for %q", mime)
+	}
+
+	return enc(w, r, code, data)
+}
+
+// negotiate picks the mime type to render with, preferring ?format=, then
+// the file extension of the request path, then the Accept header, and
+// finally the configured DefaultMIME.
+func (rr *Renderer) negotiate(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if mime, ok := formatAliases[format]; ok {
+			return mime
+		}
+		return format
+	}
+
+	if ext := strings.TrimPrefix(filepath.Ext(r.URL.Path), "."); ext != "" {
+		if mime, ok := formatAliases[ext]; ok {
+			return mime
+		}
+	}
+
+	for _, mime := range parseAccept(r.Header.Get("Accept")) {
+		if mime == "*/*" {
+			return rr.defaultMIME
+		}
+		if _, ok := rr.encoders[mime]; ok {
+			return mime
+		}
+	}
+
+	return rr.defaultMIME
+}
+
+// encodeHTML renders data using rr.Templates, reading the template name from
+// data["_template"] when data is a V.
+func (rr *Renderer) encodeHTML(w http.ResponseWriter, r *http.Request, code int, data interface{}) error {
+	name := ""
+	if v, ok := data.(V); ok {
+		name, _ = v["_template"].(string)
+	}
+	return rr.Templates.HTML(w, r, code, name, data)
+}
+
+// encodeYAML outputs the data encoded as YAML.
+func encodeYAML(w http.ResponseWriter, r *http.Request, code int, data interface{}) error {
+	w.Header().Add("content-type", "application/yaml; charset=utf-8")
+	w.WriteHeader(code)
+	return yaml.NewEncoder(w).Encode(data)
+}
+
+// encodeText outputs data formatted with %v as plain text.
+func encodeText(w http.ResponseWriter, r *http.Request, code int, data interface{}) error {
+	w.Header().Add("content-type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, err := fmt.Fprintf(w, "%v", data)
+	return err
+}
+
+// parseAccept parses an Accept header into mime types ordered by descending
+// q-value.
+func parseAccept(header string) []string {
+	type candidate struct {
+		mime string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ";")
+		mime := strings.TrimSpace(fields[0])
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		candidates = append(candidates, candidate{mime, q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	mimes := make([]string, len(candidates))
+	for i, c := range candidates {
+		mimes[i] = c.mime
+	}
+	return mimes
+}
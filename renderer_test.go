@@ -0,0 +1,60 @@
+package minions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormatQueryParam(t *testing.T) {
+	rr := NewRenderer(Templates{})
+	req := httptest.NewRequest(http.MethodGet, "/report?format=xml", nil)
+
+	if mime := rr.negotiate(req); mime != "application/xml" {
+		t.Errorf("expected application/xml, got %q", mime)
+	}
+}
+
+func TestNegotiateFileExtension(t *testing.T) {
+	rr := NewRenderer(Templates{})
+	req := httptest.NewRequest(http.MethodGet, "/report.yaml", nil)
+
+	if mime := rr.negotiate(req); mime != "application/yaml" {
+		t.Errorf("expected application/yaml, got %q", mime)
+	}
+}
+
+func TestNegotiateAcceptHeader(t *testing.T) {
+	rr := NewRenderer(Templates{})
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("Accept", "text/plain;q=0.5, application/json;q=0.9")
+
+	if mime := rr.negotiate(req); mime != "application/json" {
+		t.Errorf("expected application/json, got %q", mime)
+	}
+}
+
+func TestNegotiateFallsBackToDefaultMIME(t *testing.T) {
+	rr := NewRenderer(Templates{})
+	rr.DefaultMIME("text/plain")
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set("Accept", "application/msgpack")
+
+	if mime := rr.negotiate(req); mime != "text/plain" {
+		t.Errorf("expected text/plain, got %q", mime)
+	}
+}
+
+func TestRenderNotAcceptable(t *testing.T) {
+	rr := NewRenderer(Templates{})
+	rr.DefaultMIME("application/msgpack")
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+
+	if err := rr.Render(rec, req, http.StatusOK, V{}); err == nil {
+		t.Error("expected an error for an unregistered mime type")
+	}
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("expected status %d, got %d", http.StatusNotAcceptable, rec.Code)
+	}
+}
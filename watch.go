@@ -0,0 +1,133 @@
+package minions
+
+import (
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// NewTemplatesWatched creates a new template collection like NewTemplates,
+// but instead of reparsing the whole directory on every request it watches
+// dir for filesystem changes and reparses only the template that changed.
+// The parsed template is cached in an atomic.Value, so HTML and Execute
+// never take a lock on the hot path.
+func NewTemplatesWatched(dir string) (Templates, error) {
+	tpl := NewTemplates(dir, false)
+	tpl.watched = true
+	tpl.cache = &atomic.Value{}
+	tpl.onReloadError = &atomic.Value{}
+
+	loaded, err := tpl.Load()
+	if err != nil {
+		return tpl, err
+	}
+	tpl.cache.Store(loaded.templates)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return tpl, err
+	}
+	tpl.watcher = watcher
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return tpl, err
+	}
+
+	go tpl.watch()
+	return tpl, nil
+}
+
+// OnReloadError registers a callback invoked from the watching goroutine
+// whenever a watched template fails to reparse; the previously parsed
+// template stays in use. Watcher errors are passed to it with an empty path.
+// The return value is the updated template.
+func (tpl Templates) OnReloadError(fn func(path string, err error)) Templates {
+	if tpl.onReloadError != nil {
+		tpl.onReloadError.Store(fn)
+	}
+	return tpl
+}
+
+// reloadErrorFn returns the currently registered OnReloadError callback, or
+// nil if none was set.
+func (tpl Templates) reloadErrorFn() func(path string, err error) {
+	if tpl.onReloadError == nil {
+		return nil
+	}
+	fn, _ := tpl.onReloadError.Load().(func(path string, err error))
+	return fn
+}
+
+// watch reparses a single changed template whenever fsnotify reports a
+// Write, Chmod, Create or Rename event for it, until the watcher is closed.
+func (tpl Templates) watch() {
+	for {
+		select {
+		case event, ok := <-tpl.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Chmod|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			info, err := os.Stat(event.Name)
+			if err != nil || info.IsDir() {
+				continue
+			}
+
+			if err := tpl.reparse(event.Name); err != nil {
+				if fn := tpl.reloadErrorFn(); fn != nil {
+					fn(event.Name, err)
+				}
+			}
+
+		case err, ok := <-tpl.watcher.Errors:
+			if !ok {
+				return
+			}
+			if fn := tpl.reloadErrorFn(); fn != nil {
+				fn("", err)
+			}
+		}
+	}
+}
+
+// reparse reloads a single template from disk and atomically swaps it into
+// the cache, leaving every other already parsed template untouched.
+func (tpl Templates) reparse(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	current := tpl.cache.Load().(*template.Template)
+	clone, err := current.Clone()
+	if err != nil {
+		return err
+	}
+
+	clone, err = clone.
+		New(strings.TrimPrefix(path, tpl.dir)).
+		Parse(string(b))
+	if err != nil {
+		return err
+	}
+
+	tpl.cache.Store(clone)
+	return nil
+}
@@ -0,0 +1,83 @@
+package minions
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTemplatesWatchedReparsesChangedFile(t *testing.T) {
+	dir := t.TempDir() + string(os.PathSeparator)
+	path := filepath.Join(dir, "hello.html")
+	if err := os.WriteFile(path, []byte("hello v1"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tpl, err := NewTemplatesWatched(dir)
+	if err != nil {
+		t.Fatalf("NewTemplatesWatched failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := tpl.Execute(rec, "hello.html", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if rec.Body.String() != "hello v1" {
+		t.Fatalf("expected %q, got %q", "hello v1", rec.Body.String())
+	}
+
+	if err := os.WriteFile(path, []byte("hello v2"), 0644); err != nil {
+		t.Fatalf("failed to rewrite template: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rec = httptest.NewRecorder()
+		if err := tpl.Execute(rec, "hello.html", nil); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if rec.Body.String() == "hello v2" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected template to reparse to %q, got %q", "hello v2", rec.Body.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestNewTemplatesWatchedOnReloadError(t *testing.T) {
+	dir := t.TempDir() + string(os.PathSeparator)
+	path := filepath.Join(dir, "bad.html")
+	if err := os.WriteFile(path, []byte("{{ .Valid }}"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tpl, err := NewTemplatesWatched(dir)
+	if err != nil {
+		t.Fatalf("NewTemplatesWatched failed: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	tpl = tpl.OnReloadError(func(path string, err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	if err := os.WriteFile(path, []byte("{{ if }}"), 0644); err != nil {
+		t.Fatalf("failed to rewrite template: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected a parse error to be reported")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected OnReloadError to be invoked")
+	}
+}